@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caddyserver/certmagic"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/providers/dns"
+)
+
+// ACMEManager obtains and renews the certificate described by an
+// ACMEIssuerConfig and makes it available to gRPC and AMQP TLS
+// configuration, independent of the CA's own issuance path. It's used to
+// bootstrap a service's own internal mesh certificate from an ACME CA
+// instead of a static PEM file.
+type ACMEManager struct {
+	config ACMEIssuerConfig
+	magic  *certmagic.Config
+
+	mu     sync.RWMutex
+	status ACMEStatus
+}
+
+// ACMEStatus summarizes the managed certificate as Start left it, and is
+// served as JSON on DebugAddr. It is set only by Start: renewals that
+// certmagic performs in the background afterwards do not update it.
+type ACMEStatus struct {
+	Domains []string `json:"domains"`
+	// LastRenewal is when Start's initial issuance completed.
+	LastRenewal time.Time `json:"lastRenewal"`
+	// LastError is the error from a failed initial issuance in Start.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// NewACMEManager constructs an ACMEManager from the given config. It does
+// not contact the ACME server until Start is called.
+func NewACMEManager(c ACMEIssuerConfig) (*ACMEManager, error) {
+	if !c.AgreeToTOS {
+		return nil, fmt.Errorf("ACME issuer config must set AgreeToTOS")
+	}
+	if len(c.Domains) == 0 {
+		return nil, fmt.Errorf("ACME issuer config must list at least one domain")
+	}
+
+	certmagic.Default.Email = c.Email
+	certmagic.Default.Agreed = c.AgreeToTOS
+	certmagic.Default.CA = c.DirectoryURL
+	if c.CachePath != "" {
+		certmagic.Default.Storage = &certmagic.FileStorage{Path: c.CachePath}
+	}
+
+	magic := certmagic.NewDefault()
+	if c.DNSChallenge != nil {
+		provider, err := buildDNSProvider(*c.DNSChallenge)
+		if err != nil {
+			return nil, fmt.Errorf("configuring DNS-01 challenge provider: %w", err)
+		}
+		// DNS-01 lets us issue for hosts that aren't reachable on 80/443,
+		// which is the common case for internal-only service names.
+		magic.Issuer = certmagic.NewACMEIssuer(magic, certmagic.ACMEIssuer{
+			CA:                      c.DirectoryURL,
+			Email:                   c.Email,
+			Agreed:                  c.AgreeToTOS,
+			DisableHTTPChallenge:    true,
+			DisableTLSALPNChallenge: true,
+			DNS01Solver:             &certmagic.DNS01Solver{DNSProvider: provider},
+		})
+	}
+
+	return &ACMEManager{
+		config: c,
+		magic:  magic,
+	}, nil
+}
+
+// dnsProviderTokenEnvVar maps a DNSChallengeConfig.Provider name to the
+// environment variable lego's DNS-01 provider for it reads a single API
+// token from. Only single-token providers are supported this way; a
+// provider needing more than one credential (e.g. an access key pair)
+// must have its credentials set directly in the environment before
+// Boulder starts, with DNSChallengeConfig.APITokenFile left empty.
+var dnsProviderTokenEnvVar = map[string]string{
+	"cloudflare":   "CLOUDFLARE_DNS_API_TOKEN",
+	"digitalocean": "DO_AUTH_TOKEN",
+	"gandi":        "GANDI_PERSONAL_ACCESS_TOKEN",
+	"namecheap":    "NAMECHEAP_API_KEY",
+}
+
+// buildDNSProvider constructs the lego DNS-01 challenge.Provider named by
+// c.Provider, exporting the token at c.APITokenFile into the environment
+// variable that provider expects, if one is configured.
+func buildDNSProvider(c DNSChallengeConfig) (challenge.Provider, error) {
+	if c.APITokenFile != "" {
+		envVar, ok := dnsProviderTokenEnvVar[c.Provider]
+		if !ok {
+			return nil, fmt.Errorf("no single-token env var known for DNS provider %q; set its credentials in the environment directly and leave APITokenFile empty", c.Provider)
+		}
+		token, err := ioutil.ReadFile(c.APITokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading DNS provider API token: %w", err)
+		}
+		if err := os.Setenv(envVar, strings.TrimSpace(string(token))); err != nil {
+			return nil, fmt.Errorf("setting %s: %w", envVar, err)
+		}
+	}
+	return dns.NewDNSChallengeProviderByName(c.Provider)
+}
+
+// Start obtains a certificate for all configured domains, blocking until
+// the first issuance succeeds, then renews it in the background for as
+// long as ctx is not cancelled.
+func (m *ACMEManager) Start(ctx context.Context) error {
+	if err := m.magic.ManageSync(ctx, m.config.Domains); err != nil {
+		m.mu.Lock()
+		m.status.LastError = err.Error()
+		m.mu.Unlock()
+		return fmt.Errorf("obtaining initial ACME certificate: %w", err)
+	}
+
+	if m.config.RenewalWindow.Duration > 0 {
+		if err := m.applyRenewalWindow(ctx); err != nil {
+			return fmt.Errorf("applying configured renewal window: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	m.status = ACMEStatus{
+		Domains:     m.config.Domains,
+		LastRenewal: time.Now(),
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// applyRenewalWindow translates the configured absolute RenewalWindow into
+// certmagic.Config.RenewalWindowRatio, the only renewal-timing knob
+// certmagic exposes. The ratio is derived from the lifetime of the
+// certificate ManageSync just obtained, since RenewalWindow itself can't
+// be applied directly.
+func (m *ACMEManager) applyRenewalWindow(ctx context.Context) error {
+	cert, err := m.magic.CacheManagedCertificate(ctx, m.config.Domains[0])
+	if err != nil {
+		return fmt.Errorf("loading issued certificate to compute its lifetime: %w", err)
+	}
+	if cert.Leaf == nil {
+		return fmt.Errorf("issued certificate has no parsed leaf to compute a lifetime from")
+	}
+
+	lifetime := cert.Leaf.NotAfter.Sub(cert.Leaf.NotBefore)
+	if lifetime <= 0 {
+		return fmt.Errorf("issued certificate has a non-positive lifetime")
+	}
+	ratio := float64(m.config.RenewalWindow.Duration) / float64(lifetime)
+	if ratio <= 0 || ratio >= 1 {
+		return fmt.Errorf("renewal window %s doesn't fit within the certificate's %s lifetime", m.config.RenewalWindow.Duration, lifetime)
+	}
+
+	m.magic.RenewalWindowRatio = ratio
+	return nil
+}
+
+// GetCertificate is suitable for use as tls.Config.GetCertificate, letting
+// GRPCServerConfig and AMQPConfig TLS listeners serve the ACME-issued
+// certificate.
+func (m *ACMEManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return m.magic.GetCertificate(hello)
+}
+
+// StatusHandler returns an http.Handler reporting the manager's ACMEStatus,
+// suitable for mounting on DebugAddr.
+func (m *ACMEManager) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		status := m.status
+		m.mu.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	})
+}
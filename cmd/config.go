@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,19 +12,29 @@ import (
 	cfsslConfig "github.com/cloudflare/cfssl/config"
 	"github.com/letsencrypt/pkcs11key"
 
+	"github.com/letsencrypt/boulder/cmd/secrets"
 	"github.com/letsencrypt/boulder/core"
 )
 
-// PasswordConfig either contains a password or the path to a file
-// containing a password
+// PasswordConfig either contains a password, the path to a file containing
+// a password, or a SecretRef naming a password held by an external secret
+// provider.
 type PasswordConfig struct {
 	Password     string
 	PasswordFile string
+	// SecretRef, if set, takes precedence over Password and PasswordFile
+	// and is resolved via the secrets package. Supported forms are
+	// "vault://path#field", "consul://kv/path", "env://VAR", and
+	// "file://path" (equivalent to PasswordFile).
+	SecretRef string
 }
 
-// Pass returns a password, either directly from the configuration
-// struct or by reading from a specified file
-func (pc *PasswordConfig) Pass() (string, error) {
+// Pass returns a password, resolving it via SecretRef if set, otherwise
+// falling back to PasswordFile or the literal Password as before.
+func (pc *PasswordConfig) Pass(ctx context.Context) (string, error) {
+	if pc.SecretRef != "" {
+		return secrets.Resolve(ctx, pc.SecretRef)
+	}
 	if pc.PasswordFile != "" {
 		contents, err := ioutil.ReadFile(pc.PasswordFile)
 		if err != nil {
@@ -41,6 +52,15 @@ type ServiceConfig struct {
 	DebugAddr string
 	AMQP      *AMQPConfig
 	GRPC      *GRPCServerConfig
+	// ACME configures automatic issuance and renewal of this service's own
+	// TLS/mTLS certificates via an external ACME CA. If nil, TLS credentials
+	// are taken from the static paths in GRPCServerConfig/AMQPConfig/TLSConfig
+	// as before.
+	ACME *ACMEIssuerConfig
+	// Prometheus configures an HTTP endpoint exposing this service's
+	// metrics in Prometheus's exposition format, alongside its existing
+	// Statsd reporting.
+	Prometheus *PrometheusConfig
 }
 
 // DBConfig defines how to connect to a database. The connect string may be
@@ -50,13 +70,21 @@ type DBConfig struct {
 	DBConnect string
 	// A file containing a connect URL for the DB.
 	DBConnectFile string
-	MaxDBConns    int
+	// SecretRef, if set, takes precedence over DBConnect and
+	// DBConnectFile and is resolved via the secrets package. This is the
+	// usual way to point at a Vault-issued, leased DB credential.
+	SecretRef  string
+	MaxDBConns int
 }
 
-// URL returns the DBConnect URL represented by this DBConfig object, either
-// loading it from disk or returning a default value. Leading and trailing
-// whitespace is stripped.
-func (d *DBConfig) URL() (string, error) {
+// URL returns the DBConnect URL represented by this DBConfig object,
+// resolving it via SecretRef if set, otherwise loading it from disk or
+// returning a default value. Leading and trailing whitespace is stripped.
+func (d *DBConfig) URL(ctx context.Context) (string, error) {
+	if d.SecretRef != "" {
+		url, err := secrets.Resolve(ctx, d.SecretRef)
+		return strings.TrimSpace(url), err
+	}
 	if d.DBConnectFile != "" {
 		url, err := ioutil.ReadFile(d.DBConnectFile)
 		return strings.TrimSpace(string(url)), err
@@ -196,6 +224,43 @@ type TLSConfig struct {
 	CACertFile *string
 }
 
+// ACMEIssuerConfig configures an ACME client that a service can use to
+// obtain and automatically renew the TLS certificate it presents for its
+// own internal mTLS (gRPC, AMQP) and debug endpoints. This is independent
+// of IssuerConfig, which configures the certificates Boulder's CA issues
+// to subscribers.
+type ACMEIssuerConfig struct {
+	// Email is the contact address given to the ACME server when
+	// registering an account.
+	Email string
+	// DirectoryURL is the ACME server's directory endpoint, e.g.
+	// "https://acme-v02.api.letsencrypt.org/directory".
+	DirectoryURL string
+	// Domains is the list of names to request a certificate for.
+	Domains []string
+	// CachePath is the directory where issued certificates, keys, and
+	// account data are cached between runs.
+	CachePath string
+	// AgreeToTOS must be true, or certificate issuance will fail.
+	AgreeToTOS bool
+	// DNSChallenge selects and configures a DNS-01 challenge provider. If
+	// nil, the HTTP-01 and TLS-ALPN-01 challenges are used instead, which
+	// require the issuing host to be reachable on ports 80/443.
+	DNSChallenge *DNSChallengeConfig
+	// RenewalWindow is how long before a certificate's expiry renewal is
+	// attempted. If zero, a sensible default is used.
+	RenewalWindow ConfigDuration
+}
+
+// DNSChallengeConfig selects a DNS-01 challenge provider for ACME issuance
+// and the credentials it needs to create TXT records.
+type DNSChallengeConfig struct {
+	Provider string
+	// APITokenFile is a file containing the API token for the selected DNS
+	// provider.
+	APITokenFile string
+}
+
 // RPCServerConfig contains configuration particular to a specific RPC server
 // type (e.g. RA, SA, etc)
 type RPCServerConfig struct {
@@ -254,6 +319,27 @@ type StatsdConfig struct {
 	Prefix string
 }
 
+// PrometheusConfig defines the config for a service's Prometheus metrics
+// endpoint. ServiceConfig carries it in its Prometheus field; a service's
+// StatsdConfig stays in use alongside it during the deprecation cycle in
+// which both backends are populated, and once dashboards have moved over,
+// StatsdConfig can be dropped.
+type PrometheusConfig struct {
+	// ListenAddr is the address to serve the metrics endpoint on, e.g.
+	// ":9090". If empty, the Prometheus endpoint is not started.
+	ListenAddr string
+	// Path is the HTTP path metrics are served on, defaulting to
+	// "/metrics" if empty.
+	Path string
+	// BasicAuth, if set, requires HTTP basic auth on the metrics
+	// endpoint, using PasswordConfig so the password can come from a
+	// file or a SecretRef instead of being written in plaintext.
+	BasicAuth *PasswordConfig
+	// TLS, if set, serves the metrics endpoint over HTTPS instead of
+	// plain HTTP.
+	TLS *TLSConfig
+}
+
 // ConfigDuration is just an alias for time.Duration that allows
 // serialization to YAML as well as JSON.
 type ConfigDuration struct {
@@ -316,6 +402,44 @@ type GRPCClientConfig struct {
 	ClientCertificatePath string
 	ClientKeyPath         string
 	Timeout               ConfigDuration
+	// Discovery, if set, resolves ServerAddresses dynamically instead of
+	// treating them as a static list, so scaling RA/VA/SA/CA/Publisher
+	// doesn't require a config rewrite. Supported schemes are
+	// "consul://service-name[?tag=...]", "dns+srv://_grpc._tcp.name", and
+	// "k8s://namespace/service"; entries in ServerAddresses that don't
+	// match one of these schemes are resolved as before (or ignored, if
+	// Discovery is set). See grpc/resolvers for the resolver.Builder
+	// implementations.
+	Discovery *GRPCDiscoveryConfig
+}
+
+// GRPCDiscoveryConfig configures dynamic resolution of a gRPC client's
+// server addresses via Consul, DNS SRV records, or the Kubernetes API,
+// instead of a static address list.
+type GRPCDiscoveryConfig struct {
+	// Target is the discovery URL, e.g. "consul://ra?tag=canary",
+	// "dns+srv://_grpc._tcp.ra.service.consul", or "k8s://default/ra".
+	Target string
+	// ReResolveInterval controls how often the resolver re-queries its
+	// backend for address changes, in addition to any backend-native
+	// push/watch mechanism.
+	ReResolveInterval ConfigDuration
+	// HealthCheck restricts the resolved address set using whatever
+	// liveness signal the scheme has available, not gRPC's standard
+	// health-checking protocol: for consul:// it's Consul's passingOnly
+	// health-check filter; for dns+srv:// it's a bare TCP dial to each
+	// address (which only proves a port is open, not that the gRPC
+	// server behind it is healthy); for k8s:// it excludes endpoints in
+	// NotReadyAddresses.
+	HealthCheck bool
+
+	// TLS and Token configure access to the discovery backend itself
+	// (e.g. a Consul or Kubernetes API server behind mTLS), mirroring the
+	// TLS options used to reach Vault/Consul for secrets.
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyPath  string
+	Token          string
 }
 
 // GRPCServerConfig contains the information needed to run a gRPC service
@@ -0,0 +1,157 @@
+// Package configloader loads Boulder service configs from either JSON or
+// YAML, auto-detected by file extension, with "${ENV_VAR:-default}"
+// interpolation and "!include other.yaml" directives, then validates the
+// fully-merged document against an in-tree JSON Schema before unmarshaling
+// it into the caller's config struct. This replaces the historical
+// json.Unmarshal-directly-into-a-struct approach, which silently turns a
+// misspelled field (e.g. AkamaiPurgeRetries) into a zero value instead of
+// an error.
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Load reads path, auto-detecting JSON vs YAML from its extension
+// (".json" vs ".yaml"/".yml"), expands "!include" directives and
+// "${VAR:-default}" environment interpolation, validates the result
+// against the JSON Schema registered under schemaName (if any), and
+// unmarshals it into v.
+func Load(path string, schemaName string, v interface{}) error {
+	raw, err := readExpanded(path)
+	if err != nil {
+		return err
+	}
+
+	// Schema validation and unmarshaling both want JSON, so normalize
+	// YAML input to JSON once up front.
+	asJSON, err := toJSON(path, raw)
+	if err != nil {
+		return fmt.Errorf("configloader: %s: %w", path, err)
+	}
+
+	if schemaName != "" {
+		if err := Validate(schemaName, asJSON); err != nil {
+			return fmt.Errorf("configloader: %s: %w", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(asJSON, v); err != nil {
+		return fmt.Errorf("configloader: %s: %w", path, err)
+	}
+	return nil
+}
+
+// readExpanded reads path, recursively expanding any "!include other.yaml"
+// directives (resolved relative to the including file's directory) and
+// interpolating "${VAR}"/"${VAR:-default}" against the process
+// environment.
+func readExpanded(path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("configloader: reading %s: %w", path, err)
+	}
+
+	contents, err = expandIncludes(filepath.Dir(path), contents)
+	if err != nil {
+		return nil, err
+	}
+
+	return interpolateEnv(contents), nil
+}
+
+var includeRE = regexp.MustCompile(`(?m)^(\s*)!include\s+(\S+)\s*$`)
+
+// expandIncludes replaces each "!include path" line with the (recursively
+// expanded) contents of path, indented to match the directive.
+func expandIncludes(dir string, contents []byte) ([]byte, error) {
+	var outerErr error
+	expanded := includeRE.ReplaceAllFunc(contents, func(match []byte) []byte {
+		groups := includeRE.FindSubmatch(match)
+		indent, rel := string(groups[1]), string(groups[2])
+
+		included, err := readExpanded(filepath.Join(dir, rel))
+		if err != nil {
+			outerErr = err
+			return match
+		}
+		return indentLines(included, indent)
+	})
+	if outerErr != nil {
+		return nil, outerErr
+	}
+	return expanded, nil
+}
+
+func indentLines(contents []byte, indent string) []byte {
+	lines := strings.Split(strings.TrimRight(string(contents), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+var envRE = regexp.MustCompile(`\$\{(\w+)(:-([^}]*))?\}`)
+
+// interpolateEnv replaces "${VAR}" with the value of the VAR environment
+// variable, and "${VAR:-default}" with that value or default if VAR is
+// unset or empty.
+func interpolateEnv(contents []byte) []byte {
+	return envRE.ReplaceAllFunc(contents, func(match []byte) []byte {
+		groups := envRE.FindSubmatch(match)
+		name, def := string(groups[1]), string(groups[3])
+		if val := os.Getenv(name); val != "" {
+			return []byte(val)
+		}
+		return []byte(def)
+	})
+}
+
+func toJSON(path string, raw []byte) ([]byte, error) {
+	if isYAML(path) {
+		var generic interface{}
+		if err := yaml.Unmarshal(raw, &generic); err != nil {
+			return nil, fmt.Errorf("parsing YAML: %w", err)
+		}
+		return json.Marshal(convertYAMLMaps(generic))
+	}
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	return bytes.TrimSpace(raw), nil
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// convertYAMLMaps recursively converts the map[interface{}]interface{}
+// values produced by gopkg.in/yaml.v2 into map[string]interface{}, which
+// is what encoding/json requires.
+func convertYAMLMaps(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			m[fmt.Sprintf("%v", k)] = convertYAMLMaps(v)
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = convertYAMLMaps(item)
+		}
+		return val
+	default:
+		return val
+	}
+}
@@ -0,0 +1,42 @@
+package configloader
+
+import "testing"
+
+func TestValidateOCSPUpdaterConfig(t *testing.T) {
+	// A config shaped the way encoding/json actually produces it: since
+	// OCSPUpdaterConfig embeds ServiceConfig and DBConfig anonymously,
+	// their fields are promoted to the top level rather than nested
+	// under a "ServiceConfig"/"DBConfig" key.
+	good := []byte(`{
+		"DebugAddr": ":8000",
+		"DBConnect": "root@tcp(127.0.0.1:3306)/boulder_ocsp",
+		"MaxDBConns": 10,
+		"NewCertificateWindow": "1h",
+		"OldOCSPWindow": "1h",
+		"MissingSCTWindow": "1h",
+		"RevokedCertificateWindow": "1h",
+		"NewCertificateBatchSize": 100,
+		"OldOCSPBatchSize": 100,
+		"MissingSCTBatchSize": 100,
+		"RevokedCertificateBatchSize": 100,
+		"OCSPMinTimeToExpiry": "72h",
+		"OldestIssuedSCT": "72h",
+		"AkamaiBaseURL": "https://akamai.example.com",
+		"AkamaiClientToken": "token",
+		"AkamaiClientSecret": "secret",
+		"AkamaiAccessToken": "access",
+		"AkamaiPurgeRetries": 3,
+		"AkamaiPurgeRetryBackoff": "1s",
+		"SignFailureBackoffFactor": 1.5,
+		"SignFailureBackoffMax": "1h"
+	}`)
+
+	if err := Validate("OCSPUpdaterConfig", good); err != nil {
+		t.Errorf("Validate rejected a known-good config: %s", err)
+	}
+
+	typoed := []byte(`{"AkamaiPurgeRetrys": 3}`)
+	if err := Validate("OCSPUpdaterConfig", typoed); err == nil {
+		t.Error("Validate accepted a config with a misspelled field")
+	}
+}
@@ -0,0 +1,42 @@
+package configloader
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Validate checks doc (a JSON document) against the JSON Schema shipped
+// in-tree as schemas/<name>.json - e.g. name "CAConfig" validates against
+// schemas/CAConfig.json. If no schema file exists for name, Validate is a
+// no-op, so new config structs don't need a schema before they can be
+// loaded.
+func Validate(name string, doc []byte) error {
+	schemaPath := fmt.Sprintf("schemas/%s.json", name)
+	schemaBytes, err := schemaFS.ReadFile(schemaPath)
+	if err != nil {
+		// No schema shipped for this config type; nothing to validate.
+		return nil
+	}
+
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewBytesLoader(schemaBytes),
+		gojsonschema.NewBytesLoader(doc),
+	)
+	if err != nil {
+		return fmt.Errorf("validating against schema %s: %w", schemaPath, err)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	err = fmt.Errorf("%d schema validation error(s) against %s", len(result.Errors()), schemaPath)
+	for _, e := range result.Errors() {
+		err = fmt.Errorf("%w\n  %s: %s", err, e.Field(), e.Description())
+	}
+	return err
+}
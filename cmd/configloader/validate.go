@@ -0,0 +1,40 @@
+package configloader
+
+import (
+	"fmt"
+	"io"
+)
+
+// Validatable pairs a config file's Path with the config struct Name used
+// to look up its schema, e.g. "CAConfig" for cmd.CAConfig. Passing an
+// unknown name is not an error - see Validate - so new config types work
+// before a schema is written for them.
+type Validatable struct {
+	Name string
+	Path string
+}
+
+// RunValidate implements the "boulder config validate <file>" subcommand:
+// it loads path (expanding includes and env interpolation the same way
+// Load does) and checks it against the schema for name, printing the
+// result, including any schema errors, to out. It returns a non-nil error
+// if the file can't be read or parsed (nothing is printed to out then) or
+// if validation failed, so callers can use it to set the process exit code.
+func RunValidate(out io.Writer, name, path string) error {
+	raw, err := readExpanded(path)
+	if err != nil {
+		return err
+	}
+	asJSON, err := toJSON(path, raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	err = Validate(name, asJSON)
+	if err != nil {
+		fmt.Fprintf(out, "%s: %v\n", path, err)
+		return err
+	}
+	fmt.Fprintf(out, "%s: OK\n", path)
+	return nil
+}
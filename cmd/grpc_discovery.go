@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/letsencrypt/boulder/grpc/resolvers"
+)
+
+// Builder returns the resolver.Builder that should be passed to
+// grpc.WithResolvers when dialing a GRPCClientConfig with this Discovery
+// set, configured with d's re-resolve interval, health-check filtering,
+// and discovery-backend TLS/token settings.
+func (d *GRPCDiscoveryConfig) Builder() (resolver.Builder, error) {
+	u, err := url.Parse(d.Target)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Discovery.Target %q: %w", d.Target, err)
+	}
+
+	cfg := resolvers.Config{
+		ReResolveInterval: d.ReResolveInterval.Duration,
+		HealthCheck:       d.HealthCheck,
+		CACertFile:        d.CACertFile,
+		ClientCertFile:    d.ClientCertFile,
+		ClientKeyPath:     d.ClientKeyPath,
+		Token:             d.Token,
+	}
+
+	switch u.Scheme {
+	case "consul":
+		return resolvers.NewConsulBuilder(cfg), nil
+	case "dns+srv":
+		return resolvers.NewDNSSRVBuilder(cfg), nil
+	case "k8s":
+		return resolvers.NewK8sBuilder(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported Discovery.Target scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewPrometheusServer builds the *http.Server that serves c's metrics
+// endpoint, registering the standard Go runtime and process collectors
+// alongside reg's service-specific metrics. It does not start listening;
+// call Serve or ListenAndServe(TLS) on the result.
+func NewPrometheusServer(c *PrometheusConfig, reg *prometheus.Registry) (*http.Server, error) {
+	reg.MustRegister(prometheus.NewGoCollector())
+	reg.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	path := c.Path
+	if path == "" {
+		path = "/metrics"
+	}
+
+	mux := http.NewServeMux()
+	handler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	if c.BasicAuth != nil {
+		var err error
+		handler, err = withBasicAuth(handler, c.BasicAuth)
+		if err != nil {
+			return nil, fmt.Errorf("configuring metrics endpoint basic auth: %w", err)
+		}
+	}
+	mux.Handle(path, handler)
+
+	server := &http.Server{
+		Addr:    c.ListenAddr,
+		Handler: mux,
+	}
+
+	if c.TLS != nil {
+		cert, err := tls.LoadX509KeyPair(*c.TLS.CertFile, *c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading metrics endpoint TLS certificate: %w", err)
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	return server, nil
+}
+
+// basicAuthUsername is the fixed username the metrics endpoint expects;
+// only the password comes from PrometheusConfig.BasicAuth.
+const basicAuthUsername = "prometheus"
+
+// withBasicAuth wraps next so that requests must present the username
+// "prometheus" and the password from pc, both compared in constant time.
+func withBasicAuth(next http.Handler, pc *PasswordConfig) (http.Handler, error) {
+	want, err := pc.Pass(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(basicAuthUsername)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(want)) == 1
+		if !ok || !userOK || !passOK {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// ConsulConfig configures a ConsulProvider.
+type ConsulConfig struct {
+	Address string
+	Token   string
+
+	// TLS options mirror VaultConfig's: a CA cert to verify the server,
+	// and an optional client cert/key pair for client auth.
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// ConsulProvider resolves SecretRef values of the form "consul://kv/path"
+// by reading the named key out of Consul's KV store.
+type ConsulProvider struct {
+	client *consulapi.Client
+}
+
+// NewConsulProvider returns a ConsulProvider configured per c.
+func NewConsulProvider(c ConsulConfig) (*ConsulProvider, error) {
+	cfg := consulapi.DefaultConfig()
+	cfg.Address = c.Address
+	cfg.Token = c.Token
+	if c.CACertFile != "" || c.ClientCertFile != "" {
+		cfg.TLSConfig = consulapi.TLSConfig{
+			CAFile:   c.CACertFile,
+			CertFile: c.ClientCertFile,
+			KeyFile:  c.ClientKeyFile,
+		}
+	}
+
+	client, err := consulapi.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating Consul client: %w", err)
+	}
+	return &ConsulProvider{client: client}, nil
+}
+
+// Fetch implements Provider. ref is the KV key, with any leading "kv/"
+// stripped (it's there for readability in the SecretRef, e.g.
+// "consul://kv/boulder/db-password").
+func (p *ConsulProvider) Fetch(_ context.Context, ref string) (string, error) {
+	key := strings.TrimPrefix(ref, "kv/")
+
+	pair, _, err := p.client.KV().Get(key, nil)
+	if err != nil {
+		return "", fmt.Errorf("reading Consul key %q: %w", key, err)
+	}
+	if pair == nil {
+		return "", fmt.Errorf("secrets: no Consul key found at %q", key)
+	}
+	return string(pair.Value), nil
+}
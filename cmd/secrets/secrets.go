@@ -0,0 +1,90 @@
+// Package secrets resolves SecretRef strings - e.g. "vault://secret/data/db#password",
+// "consul://kv/boulder/db-password", "env://DB_PASSWORD", or "file:///etc/boulder/db-password" -
+// to the secret value they name, so that PasswordConfig and DBConfig aren't limited to
+// literal strings or a single file on disk.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Provider fetches the secret named by ref, in whatever form is specific to
+// that provider (a Vault path, a Consul KV key, an env var name, a file
+// path).
+type Provider interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+// registry maps a SecretRef scheme (the part before "://") to the Provider
+// that handles it.
+var registry = map[string]Provider{
+	"env":  EnvProvider{},
+	"file": FileProvider{},
+}
+
+// Register installs a Provider for the given scheme. It's called by
+// provider implementations (e.g. Vault, Consul) that need construction-time
+// configuration, so they can be registered once at startup and then
+// referenced by scheme everywhere else.
+func Register(scheme string, p Provider) {
+	registry[scheme] = p
+}
+
+// Resolve parses ref's scheme and delegates to the registered Provider for
+// it. ref forms understood out of the box are "env://VAR" and
+// "file:///path"; "vault://..." and "consul://..." require the
+// corresponding Provider to have been Register-ed first.
+func Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, ok := splitScheme(ref)
+	if !ok {
+		// No scheme at all: treat ref as a literal value. A bare path is
+		// returned as-is, not read as a file.
+		return ref, nil
+	}
+	p, present := registry[scheme]
+	if !present {
+		return "", fmt.Errorf("secrets: no provider registered for scheme %q", scheme)
+	}
+	return p.Fetch(ctx, rest)
+}
+
+func splitScheme(ref string) (scheme string, rest string, ok bool) {
+	i := strings.Index(ref, "://")
+	if i < 0 {
+		return "", ref, false
+	}
+	return ref[:i], ref[i+len("://"):], true
+}
+
+// EnvProvider resolves a SecretRef of the form "env://VAR" to the value of
+// the named environment variable.
+type EnvProvider struct{}
+
+// Fetch implements Provider.
+func (EnvProvider) Fetch(_ context.Context, ref string) (string, error) {
+	val, present := os.LookupEnv(ref)
+	if !present {
+		return "", fmt.Errorf("secrets: environment variable %q is not set", ref)
+	}
+	return val, nil
+}
+
+// FileProvider resolves a SecretRef of the form "file:///path/to/secret" to
+// the trimmed contents of that file. This is the same behavior
+// PasswordConfig.PasswordFile and DBConfig.DBConnectFile already had; it's
+// exposed as a Provider so SecretRef can express it uniformly alongside
+// vault:// and consul://.
+type FileProvider struct{}
+
+// Fetch implements Provider.
+func (FileProvider) Fetch(_ context.Context, ref string) (string, error) {
+	contents, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\n"), nil
+}
@@ -0,0 +1,249 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// VaultAuthConfig selects how a VaultProvider authenticates to Vault.
+// Exactly one of Token, AppRole, or Kubernetes should be set.
+type VaultAuthConfig struct {
+	// Token is a static Vault token. Prefer AppRole or Kubernetes for
+	// anything longer-lived than local testing.
+	Token string
+
+	AppRole *VaultAppRoleConfig
+
+	Kubernetes *VaultKubernetesConfig
+}
+
+// VaultAppRoleConfig authenticates to Vault using the AppRole auth method.
+type VaultAppRoleConfig struct {
+	RoleID   string
+	SecretID string
+	// MountPath is the AppRole auth method's mount point, defaulting to
+	// "approle" if empty.
+	MountPath string
+}
+
+// VaultKubernetesConfig authenticates to Vault using the Kubernetes auth
+// method, exchanging the pod's projected service account token for a
+// Vault token.
+type VaultKubernetesConfig struct {
+	Role string
+	// JWTPath is the path to the service account token, defaulting to
+	// "/var/run/secrets/kubernetes.io/serviceaccount/token" if empty.
+	JWTPath string
+	// MountPath is the Kubernetes auth method's mount point, defaulting to
+	// "kubernetes" if empty.
+	MountPath string
+}
+
+// VaultConfig configures a VaultProvider.
+type VaultConfig struct {
+	Address string
+	Auth    VaultAuthConfig
+
+	// TLS options mirror what's needed to talk to a Vault server fronted by
+	// mTLS: a CA cert to verify the server, and an optional client
+	// cert/key pair for client auth.
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// VaultProvider resolves SecretRef values of the form
+// "vault://<path>#<field>" - e.g. "vault://secret/data/db#password" - by
+// reading the named field out of the KV secret at path.
+type VaultProvider struct {
+	client *vaultapi.Client
+}
+
+// NewVaultProvider authenticates to Vault per c.Auth and returns a
+// VaultProvider ready to Fetch secrets.
+func NewVaultProvider(ctx context.Context, c VaultConfig) (*VaultProvider, error) {
+	cfg := vaultapi.DefaultConfig()
+	cfg.Address = c.Address
+	if c.CACertFile != "" || c.ClientCertFile != "" {
+		err := cfg.ConfigureTLS(&vaultapi.TLSConfig{
+			CACert:     c.CACertFile,
+			ClientCert: c.ClientCertFile,
+			ClientKey:  c.ClientKeyFile,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("configuring Vault TLS: %w", err)
+		}
+	}
+
+	client, err := vaultapi.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating Vault client: %w", err)
+	}
+
+	token, err := vaultLogin(ctx, client, c.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("authenticating to Vault: %w", err)
+	}
+	client.SetToken(token)
+
+	return &VaultProvider{client: client}, nil
+}
+
+func vaultLogin(ctx context.Context, client *vaultapi.Client, auth VaultAuthConfig) (string, error) {
+	switch {
+	case auth.Token != "":
+		return auth.Token, nil
+
+	case auth.AppRole != nil:
+		mount := auth.AppRole.MountPath
+		if mount == "" {
+			mount = "approle"
+		}
+		secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), map[string]interface{}{
+			"role_id":   auth.AppRole.RoleID,
+			"secret_id": auth.AppRole.SecretID,
+		})
+		if err != nil {
+			return "", err
+		}
+		return secret.Auth.ClientToken, nil
+
+	case auth.Kubernetes != nil:
+		jwtPath := auth.Kubernetes.JWTPath
+		if jwtPath == "" {
+			jwtPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		}
+		mount := auth.Kubernetes.MountPath
+		if mount == "" {
+			mount = "kubernetes"
+		}
+		jwt, err := readFileTrimmed(jwtPath)
+		if err != nil {
+			return "", err
+		}
+		secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mount), map[string]interface{}{
+			"role": auth.Kubernetes.Role,
+			"jwt":  jwt,
+		})
+		if err != nil {
+			return "", err
+		}
+		return secret.Auth.ClientToken, nil
+
+	default:
+		return "", fmt.Errorf("no Vault auth method configured")
+	}
+}
+
+// Fetch implements Provider. ref is "<path>#<field>"; the secret at path is
+// read and the named field extracted.
+func (p *VaultProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok {
+		return "", fmt.Errorf("secrets: vault ref %q must be of the form path#field", ref)
+	}
+
+	secret, err := p.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("reading Vault secret %q: %w", path, err)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("secrets: no Vault secret found at %q", path)
+	}
+
+	data := secret.Data
+	// KV v2 nests the actual fields under a "data" key.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	val, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secrets: Vault secret %q has no field %q", path, field)
+	}
+	str, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("secrets: Vault secret %q field %q is not a string", path, field)
+	}
+	return str, nil
+}
+
+// WatchLease renews the lease backing ref's Vault secret as it approaches
+// expiry, calling onRotate with the freshly-read value each time the
+// underlying secret changes. It runs until ctx is cancelled. Callers
+// typically feed onRotate into cmd.Watcher.PushUpdate so a rotated
+// Vault-issued DB credential flows through the same hot-reload path as a
+// rotated file-backed one.
+func (p *VaultProvider) WatchLease(ctx context.Context, ref string, onRotate func(value string)) error {
+	path, _, ok := strings.Cut(ref, "#")
+	if !ok {
+		return fmt.Errorf("secrets: vault ref %q must be of the form path#field", ref)
+	}
+
+	// Each iteration watches one lease to expiry (or to ctx cancellation),
+	// then - on expiry - fetches the fresh credential the next iteration
+	// will watch the lease of. This loops rather than recursing so a
+	// long-lived process renewing the same ref indefinitely doesn't grow
+	// the goroutine's stack without bound.
+	for {
+		secret, err := p.client.Logical().ReadWithContext(ctx, path)
+		if err != nil {
+			return fmt.Errorf("reading Vault secret %q: %w", path, err)
+		}
+		if secret == nil || secret.LeaseID == "" {
+			// Not a leased secret (e.g. a static KV entry); nothing to
+			// renew.
+			return nil
+		}
+
+		watcher, err := p.client.NewLifetimeWatcher(&vaultapi.LifetimeWatcherInput{
+			Secret: secret,
+		})
+		if err != nil {
+			return fmt.Errorf("creating Vault lease watcher: %w", err)
+		}
+		go watcher.Start()
+
+		expired, err := watchOneLease(ctx, watcher)
+		watcher.Stop()
+		if err != nil {
+			return fmt.Errorf("Vault lease renewal failed: %w", err)
+		}
+		if !expired {
+			// ctx was cancelled.
+			return nil
+		}
+
+		// The lease expired rather than being renewed; fetch a fresh
+		// credential and loop around to watch its lease instead.
+		value, err := p.Fetch(ctx, ref)
+		if err != nil {
+			return err
+		}
+		onRotate(value)
+	}
+}
+
+// watchOneLease blocks until watcher reports the lease it's tracking has
+// expired (returns true, nil), ctx is cancelled (returns false, nil), or
+// renewal fails (returns false, err).
+func watchOneLease(ctx context.Context, watcher *vaultapi.LifetimeWatcher) (expired bool, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case err := <-watcher.DoneCh():
+			return err == nil, err
+		case <-watcher.RenewCh():
+			// Renewed in place; the credential value itself hasn't
+			// changed, so there's nothing to push. Keep watching the
+			// same lease.
+		}
+	}
+}
+
+func readFileTrimmed(path string) (string, error) {
+	return FileProvider{}.Fetch(context.Background(), path)
+}
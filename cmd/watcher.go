@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reloadable is implemented by servers (RA, CA, VA, SA, Publisher) that can
+// accept updated configuration material - a rotated TLS certificate, a new
+// DB password, etc. - without a process restart.
+type Reloadable interface {
+	// Reload is called with the path that changed and its freshly re-read
+	// contents. Implementations should be prepared for Reload to be called
+	// concurrently with in-flight requests.
+	Reload(path string, contents []byte) error
+}
+
+// watchedFile tracks the subscribers for a single watched path, plus the
+// last contents seen for it, so a directory-level fsnotify event (which
+// doesn't necessarily name this file) can be turned into a "did this file
+// actually change" decision.
+type watchedFile struct {
+	subscribers []func([]byte)
+	lastContent []byte
+}
+
+// Watcher watches config files and secret material on disk (DB connect
+// strings, AMQP server URLs, TLS certs/keys, the PA's hostname policy file,
+// etc.) and notifies subscribers when they change, so those values can be
+// reloaded without restarting the process.
+//
+// Subscribed files are watched by their containing directory rather than
+// by their own path. fsnotify watches an inode: once a file is replaced -
+// as atomic-rename-based writers like Vault Agent and Kubernetes secret
+// mounts do on every rotation - the watch on the old inode goes dead and
+// no further events arrive. Watching the directory survives that, and
+// also catches Kubernetes' "..data" symlink-swap rotation, which never
+// touches the subscribed file's own directory entry at all: any event in
+// the directory triggers a rescan of every file subscribed in it.
+type Watcher struct {
+	watcher *fsnotify.Watcher
+
+	mu   sync.Mutex
+	dirs map[string]map[string]*watchedFile // directory -> base name -> watchedFile
+	// sources holds push-only subscriptions registered via SubscribeSource,
+	// keyed by an arbitrary caller-chosen name rather than a directory/base
+	// pair, since they have no file backing them at all.
+	sources map[string]*watchedFile
+}
+
+// NewWatcher constructs a Watcher. Call Start to begin watching.
+func NewWatcher() (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating fsnotify watcher: %w", err)
+	}
+	return &Watcher{
+		watcher: fsw,
+		dirs:    make(map[string]map[string]*watchedFile),
+		sources: make(map[string]*watchedFile),
+	}, nil
+}
+
+// Subscribe registers cb to be called with the new contents of path
+// whenever it changes on disk. Multiple subscribers may watch the same
+// path, for example a DB password file that both the RA and SA consume.
+func (w *Watcher) Subscribe(path string, cb func(contents []byte)) error {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	files, present := w.dirs[dir]
+	if !present {
+		if err := w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("watching %q: %w", dir, err)
+		}
+		files = make(map[string]*watchedFile)
+		w.dirs[dir] = files
+	}
+	wf, present := files[base]
+	if !present {
+		wf = &watchedFile{}
+		// Record the contents as of subscription, so the first event in
+		// the directory only notifies if this file has actually changed.
+		wf.lastContent, _ = ioutil.ReadFile(path)
+		files[base] = wf
+	}
+	wf.subscribers = append(wf.subscribers, cb)
+	return nil
+}
+
+// SubscribeSource registers cb under key without adding an fsnotify watch,
+// for sources that aren't backed by a file at all - e.g. a Vault SecretRef -
+// and so can only ever be updated via PushUpdate.
+func (w *Watcher) SubscribeSource(key string, cb func(contents []byte)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wf, present := w.sources[key]
+	if !present {
+		wf = &watchedFile{}
+		w.sources[key] = wf
+	}
+	wf.subscribers = append(wf.subscribers, cb)
+}
+
+// PushUpdate notifies key's subscribers directly with contents, without
+// requiring a filesystem event or reading from disk. This lets sources
+// registered via SubscribeSource - most notably a Vault-issued credential
+// renewed by secrets.VaultProvider.WatchLease - feed rotations through the
+// same subscriber path as a watched file.
+func (w *Watcher) PushUpdate(key string, contents []byte) {
+	w.mu.Lock()
+	wf, present := w.sources[key]
+	var subscribers []func([]byte)
+	if present {
+		// Snapshot under the lock: SubscribeSource can append to
+		// wf.subscribers concurrently, and reading the live slice after
+		// unlocking would race with that append.
+		subscribers = append(subscribers, wf.subscribers...)
+	}
+	w.mu.Unlock()
+	if !present {
+		return
+	}
+	for _, cb := range subscribers {
+		cb(contents)
+	}
+}
+
+// Start begins processing filesystem events in the background. It returns
+// immediately; call Stop to shut it down.
+func (w *Watcher) Start() {
+	go w.run()
+}
+
+// Stop closes the underlying fsnotify watcher, ending the goroutine started
+// by Start.
+func (w *Watcher) Stop() error {
+	return w.watcher.Close()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// Any event in a watched directory - including a Rename or
+			// Remove of some other entry, such as Kubernetes' "..data"
+			// symlink swap - can mean a subscribed file's contents
+			// changed, so rescan the whole directory rather than trying
+			// to match event.Name against the subscribed base name.
+			w.rescan(filepath.Dir(event.Name))
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// rescan re-reads every file subscribed in dir and notifies subscribers
+// for any whose contents changed since the last scan.
+func (w *Watcher) rescan(dir string) {
+	type pending struct {
+		cb       func([]byte)
+		contents []byte
+	}
+	var toNotify []pending
+
+	w.mu.Lock()
+	for base, wf := range w.dirs[dir] {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, base))
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(contents, wf.lastContent) {
+			continue
+		}
+		wf.lastContent = contents
+		for _, cb := range wf.subscribers {
+			toNotify = append(toNotify, pending{cb, contents})
+		}
+	}
+	w.mu.Unlock()
+
+	for _, p := range toNotify {
+		p.cb(p.contents)
+	}
+}
@@ -0,0 +1,76 @@
+package resolvers
+
+import (
+	"fmt"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"google.golang.org/grpc/resolver"
+)
+
+// consulBuilder implements resolver.Builder for "consul://service-name[?tag=...]"
+// targets, resolving to the instances of service-name registered with
+// Consul; only those passing their health checks if Config.HealthCheck is
+// set, otherwise all of them.
+type consulBuilder struct {
+	cfg Config
+}
+
+// NewConsulBuilder returns a resolver.Builder for the "consul" scheme that
+// applies cfg's re-resolve interval, health-check filtering, and
+// TLS/token settings when reaching the Consul agent.
+func NewConsulBuilder(cfg Config) resolver.Builder {
+	return &consulBuilder{cfg: cfg}
+}
+
+func (*consulBuilder) Scheme() string { return "consul" }
+
+func (b *consulBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	service := target.URL.Host
+	if service == "" {
+		return nil, unsupportedTarget(b.Scheme(), target)
+	}
+	tag := target.URL.Query().Get("tag")
+
+	apiCfg := consulapi.DefaultConfig()
+	apiCfg.Token = b.cfg.Token
+	if b.cfg.CACertFile != "" || b.cfg.ClientCertFile != "" {
+		apiCfg.TLSConfig = consulapi.TLSConfig{
+			CAFile:   b.cfg.CACertFile,
+			CertFile: b.cfg.ClientCertFile,
+			KeyFile:  b.cfg.ClientKeyPath,
+		}
+	}
+	client, err := consulapi.NewClient(apiCfg)
+	if err != nil {
+		return nil, fmt.Errorf("resolvers: consul: creating client: %w", err)
+	}
+
+	done := make(chan struct{})
+	r := &consulResolver{done: done}
+	go pollResolver(done, cc, b.Scheme(), b.cfg.ReResolveInterval, func() ([]resolver.Address, error) {
+		entries, _, err := client.Health().Service(service, tag, b.cfg.HealthCheck, nil)
+		if err != nil {
+			return nil, fmt.Errorf("resolvers: consul: looking up %q: %w", service, err)
+		}
+		addrs := make([]resolver.Address, 0, len(entries))
+		for _, e := range entries {
+			addr := e.Service.Address
+			if addr == "" {
+				addr = e.Node.Address
+			}
+			addrs = append(addrs, resolver.Address{Addr: fmt.Sprintf("%s:%d", addr, e.Service.Port)})
+		}
+		return addrs, nil
+	})
+	return r, nil
+}
+
+type consulResolver struct {
+	done chan struct{}
+}
+
+func (r *consulResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *consulResolver) Close() {
+	close(r.done)
+}
@@ -0,0 +1,80 @@
+package resolvers
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// dnsSRVBuilder implements resolver.Builder for
+// "dns+srv://_grpc._tcp.name" targets, resolving to the targets returned by
+// an SRV lookup of the given name.
+type dnsSRVBuilder struct {
+	cfg Config
+}
+
+// NewDNSSRVBuilder returns a resolver.Builder for the "dns+srv" scheme
+// that applies cfg's re-resolve interval and, if HealthCheck is set,
+// probes each resolved address with a short TCP dial before including it.
+func NewDNSSRVBuilder(cfg Config) resolver.Builder {
+	return &dnsSRVBuilder{cfg: cfg}
+}
+
+func (*dnsSRVBuilder) Scheme() string { return "dns+srv" }
+
+func (b *dnsSRVBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	name := target.URL.Host + target.URL.Path
+	if name == "" {
+		return nil, unsupportedTarget(b.Scheme(), target)
+	}
+
+	done := make(chan struct{})
+	r := &dnsSRVResolver{done: done}
+	go pollResolver(done, cc, b.Scheme(), b.cfg.ReResolveInterval, func() ([]resolver.Address, error) {
+		_, records, err := net.LookupSRV("", "", name)
+		if err != nil {
+			return nil, fmt.Errorf("resolvers: dns+srv: looking up %q: %w", name, err)
+		}
+		addrs := make([]resolver.Address, 0, len(records))
+		for _, rec := range records {
+			addr := fmt.Sprintf("%s:%d", trimTrailingDot(rec.Target), rec.Port)
+			if b.cfg.HealthCheck && !dialHealthy(addr) {
+				continue
+			}
+			addrs = append(addrs, resolver.Address{Addr: addr})
+		}
+		return addrs, nil
+	})
+	return r, nil
+}
+
+// dialHealthy does a short TCP dial to addr, standing in for a real
+// health-check protocol since plain DNS SRV records carry no health
+// information of their own.
+func dialHealthy(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func trimTrailingDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
+
+type dnsSRVResolver struct {
+	done chan struct{}
+}
+
+func (r *dnsSRVResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *dnsSRVResolver) Close() {
+	close(r.done)
+}
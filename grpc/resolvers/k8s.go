@@ -0,0 +1,118 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"google.golang.org/grpc/resolver"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// k8sBuilder implements resolver.Builder for "k8s://namespace/service"
+// targets, resolving to the endpoints backing the named Service.
+type k8sBuilder struct {
+	cfg Config
+}
+
+// NewK8sBuilder returns a resolver.Builder for the "k8s" scheme that
+// applies cfg's re-resolve interval, TLS/token credentials for reaching
+// the API server, and Ready-only endpoint filtering when HealthCheck is
+// set.
+func NewK8sBuilder(cfg Config) resolver.Builder {
+	return &k8sBuilder{cfg: cfg}
+}
+
+func (*k8sBuilder) Scheme() string { return "k8s" }
+
+func (b *k8sBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	namespace := target.URL.Host
+	service := strings.TrimPrefix(target.URL.Path, "/")
+	if namespace == "" || service == "" {
+		return nil, unsupportedTarget(b.Scheme(), target)
+	}
+
+	restCfg, err := b.restConfig()
+	if err != nil {
+		return nil, fmt.Errorf("resolvers: k8s: building API client config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("resolvers: k8s: creating client: %w", err)
+	}
+
+	done := make(chan struct{})
+	r := &k8sResolver{done: done}
+	go pollResolver(done, cc, b.Scheme(), b.cfg.ReResolveInterval, func() ([]resolver.Address, error) {
+		endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(context.Background(), service, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("resolvers: k8s: looking up %s/%s: %w", namespace, service, err)
+		}
+		var addrs []resolver.Address
+		for _, subset := range endpoints.Subsets {
+			port := grpcPort(subset)
+			addresses := subset.Addresses
+			if !b.cfg.HealthCheck {
+				// Without health-check filtering, also include endpoints
+				// that haven't yet passed their readiness probe.
+				addresses = append(append([]corev1.EndpointAddress{}, addresses...), subset.NotReadyAddresses...)
+			}
+			for _, addr := range addresses {
+				addrs = append(addrs, resolver.Address{Addr: fmt.Sprintf("%s:%d", addr.IP, port)})
+			}
+		}
+		return addrs, nil
+	})
+	return r, nil
+}
+
+// restConfig builds the Kubernetes API client config to use: b.cfg's
+// explicit TLS/token settings if any are given, otherwise the pod's
+// in-cluster config.
+func (b *k8sBuilder) restConfig() (*rest.Config, error) {
+	if b.cfg.Token == "" && b.cfg.CACertFile == "" && b.cfg.ClientCertFile == "" {
+		return rest.InClusterConfig()
+	}
+
+	cfg := &rest.Config{
+		BearerToken: b.cfg.Token,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   b.cfg.CACertFile,
+			CertFile: b.cfg.ClientCertFile,
+			KeyFile:  b.cfg.ClientKeyPath,
+		},
+	}
+	if base, err := rest.InClusterConfig(); err == nil {
+		// Fill in the API server host from the in-cluster environment;
+		// only the credentials above are meant to override it.
+		cfg.Host = base.Host
+	}
+	return cfg, nil
+}
+
+// grpcPort picks the port named "grpc" if present, otherwise the first
+// port in the subset.
+func grpcPort(subset corev1.EndpointSubset) int32 {
+	for _, p := range subset.Ports {
+		if p.Name == "grpc" {
+			return p.Port
+		}
+	}
+	if len(subset.Ports) > 0 {
+		return subset.Ports[0].Port
+	}
+	return 0
+}
+
+type k8sResolver struct {
+	done chan struct{}
+}
+
+func (r *k8sResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *k8sResolver) Close() {
+	close(r.done)
+}
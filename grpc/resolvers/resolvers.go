@@ -0,0 +1,118 @@
+// Package resolvers implements grpc/resolver.Builder for the discovery
+// schemes a GRPCClientConfig.Discovery target may use - Consul, DNS SRV,
+// and Kubernetes - so that GRPCClientConfig.ServerAddresses can name a
+// service instead of a fixed address list.
+package resolvers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// metrics, set by RegisterMetrics, are incremented by each resolver as it
+// runs and are exposed on DebugAddr alongside the rest of a service's debug
+// handlers.
+var metricsMu sync.Mutex
+var metricsHandler func(scheme string, addressCount int, err error)
+
+// RegisterMetrics installs a callback invoked after every resolution
+// attempt by any resolver in this package, with the scheme that resolved,
+// the number of addresses found, and any error encountered. Service
+// main.go files call this once at startup to wire resolver health into
+// their DebugAddr metrics.
+func RegisterMetrics(cb func(scheme string, addressCount int, err error)) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metricsHandler = cb
+}
+
+func reportMetrics(scheme string, addressCount int, err error) {
+	metricsMu.Lock()
+	cb := metricsHandler
+	metricsMu.Unlock()
+	if cb != nil {
+		cb(scheme, addressCount, err)
+	}
+}
+
+// Config carries the per-scheme settings a GRPCDiscoveryConfig specifies,
+// without this package needing to import cmd (which imports this
+// package's builders). Build a scheme-specific resolver.Builder with
+// NewConsulBuilder/NewDNSSRVBuilder/NewK8sBuilder and pass it to
+// grpc.WithResolvers at Dial time to apply it; the zero Config is what
+// RegisterAll uses for the global, unconfigured fallback.
+type Config struct {
+	// ReResolveInterval controls how often the resolver re-queries its
+	// backend for address changes. Zero means defaultReResolveInterval.
+	ReResolveInterval time.Duration
+	// HealthCheck, for Consul, restricts results to passing health
+	// checks; for Kubernetes, restricts results to Ready endpoints
+	// (excluding NotReadyAddresses) rather than including both.
+	HealthCheck bool
+
+	// CACertFile, ClientCertFile, and ClientKeyPath configure TLS to the
+	// discovery backend itself (the Consul or Kubernetes API server).
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyPath  string
+	// Token authenticates to the discovery backend - a Consul ACL token
+	// or a Kubernetes bearer token.
+	Token string
+}
+
+// RegisterAll registers the consul, dns+srv, and k8s resolver.Builders,
+// each using the zero Config (no custom TLS/token, default interval, no
+// health-check filtering beyond each scheme's own default), with the
+// default grpc resolver registry. Service main.go files call this once at
+// startup so that a plain "consul://", "dns+srv://", or "k8s://"
+// ServerAddresses entry resolves even without an explicit
+// GRPCDiscoveryConfig. A service that does set one should instead build a
+// configured resolver.Builder and pass it via grpc.WithResolvers.
+func RegisterAll() {
+	resolver.Register(NewConsulBuilder(Config{}))
+	resolver.Register(NewDNSSRVBuilder(Config{}))
+	resolver.Register(NewK8sBuilder(Config{}))
+}
+
+// defaultReResolveInterval is used when a Config doesn't set
+// ReResolveInterval.
+const defaultReResolveInterval = 10 * time.Second
+
+// pollResolver runs resolve in a loop every interval until done is closed,
+// pushing results to cc and reporting metrics under scheme. It's shared by
+// all three builders, which differ only in how they resolve.
+func pollResolver(done <-chan struct{}, cc resolver.ClientConn, scheme string, interval time.Duration, resolve func() ([]resolver.Address, error)) {
+	if interval <= 0 {
+		interval = defaultReResolveInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	resolveOnce := func() {
+		addrs, err := resolve()
+		if err != nil {
+			cc.ReportError(err)
+			reportMetrics(scheme, 0, err)
+			return
+		}
+		cc.UpdateState(resolver.State{Addresses: addrs})
+		reportMetrics(scheme, len(addrs), nil)
+	}
+
+	resolveOnce()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			resolveOnce()
+		}
+	}
+}
+
+func unsupportedTarget(scheme string, target resolver.Target) error {
+	return fmt.Errorf("resolvers: %s: unparseable target %q", scheme, target.URL.String())
+}
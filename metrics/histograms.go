@@ -0,0 +1,20 @@
+package metrics
+
+// Stat names for the histograms the request specifically calls out. Each
+// service's Scope.Timing call should use these rather than ad-hoc
+// strings, so the resulting Prometheus series names stay consistent
+// across RA/VA/CA/SA/Publisher/OCSP-updater.
+const (
+	// RPCLatency is the wall-clock time of a gRPC call, as observed by
+	// the client.
+	RPCLatency = "rpc_latency"
+	// IssuanceDuration is the time from CSR acceptance to signed
+	// certificate, as observed by the CA.
+	IssuanceDuration = "issuance_duration"
+	// OCSPSigningLatency is the time the CA spends signing a single OCSP
+	// response.
+	OCSPSigningLatency = "ocsp_signing_latency"
+	// CAALookupLatency is the time the VA spends resolving and
+	// evaluating CAA records for a single name.
+	CAALookupLatency = "caa_lookup_latency"
+)
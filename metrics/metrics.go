@@ -0,0 +1,148 @@
+// Package metrics provides the Scope abstraction services use to report
+// RPC latency, certificate issuance duration, OCSP signing time, CAA
+// lookup time, and other operational metrics. Scope reports to Prometheus
+// and, for the duration of the deprecation cycle described in
+// cmd.PrometheusConfig's doc comment, mirrors every observation to Statsd
+// as well.
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cactus/go-statsd-client/statsd"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Scope is the interface services use to report metrics, replacing the
+// ad-hoc statsd.Statter usages that used to be scattered across RA/VA/CA/SA/
+// Publisher/OCSP-updater.
+type Scope interface {
+	// Inc increments the counter named stat by n.
+	Inc(stat string, n int64)
+	// Gauge sets the gauge named stat to value.
+	Gauge(stat string, value float64)
+	// Timing records delta in the histogram named stat. Sub-millisecond
+	// durations are mirrored to Statsd as a decimal number of
+	// milliseconds (e.g. "0.250"), rather than being truncated to the
+	// "0" that statsd's integer-millisecond Timing call would produce.
+	Timing(stat string, delta time.Duration)
+}
+
+// scope implements Scope on top of a prometheus.Registerer and a
+// statsd.Statter.
+type scope struct {
+	namespace string
+	statter   statsd.Statter
+
+	mu       chan struct{} // acts as a mutex for the lazily-built vecs below
+	counters map[string]*prometheus.CounterVec
+	gauges   map[string]*prometheus.GaugeVec
+	timings  map[string]*prometheus.HistogramVec
+	reg      prometheus.Registerer
+}
+
+// NewScope returns a Scope that reports to reg and mirrors every
+// observation to statter. namespace is prefixed to every metric name,
+// e.g. "ra" for the Registration Authority.
+//
+// reg is typically the same *prometheus.Registry passed to
+// cmd.NewPrometheusServer, which registers the Go runtime and process
+// collectors on it; NewScope itself registers only the counters, gauges,
+// and histograms it creates on demand, so that calling both on one
+// registry doesn't double-register the runtime/process collectors.
+func NewScope(namespace string, reg prometheus.Registerer, statter statsd.Statter) Scope {
+	return &scope{
+		namespace: namespace,
+		statter:   statter,
+		reg:       reg,
+		mu:        make(chan struct{}, 1),
+		counters:  make(map[string]*prometheus.CounterVec),
+		gauges:    make(map[string]*prometheus.GaugeVec),
+		timings:   make(map[string]*prometheus.HistogramVec),
+	}
+}
+
+func (s *scope) qualify(stat string) string {
+	return fmt.Sprintf("%s_%s", s.namespace, stat)
+}
+
+func (s *scope) counter(stat string) *prometheus.CounterVec {
+	s.mu <- struct{}{}
+	defer func() { <-s.mu }()
+
+	name := s.qualify(stat)
+	c, present := s.counters[name]
+	if !present {
+		c = prometheus.NewCounterVec(prometheus.CounterOpts{Name: name}, nil)
+		s.reg.MustRegister(c)
+		s.counters[name] = c
+	}
+	return c
+}
+
+func (s *scope) gauge(stat string) *prometheus.GaugeVec {
+	s.mu <- struct{}{}
+	defer func() { <-s.mu }()
+
+	name := s.qualify(stat)
+	g, present := s.gauges[name]
+	if !present {
+		g = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name}, nil)
+		s.reg.MustRegister(g)
+		s.gauges[name] = g
+	}
+	return g
+}
+
+func (s *scope) timing(stat string) *prometheus.HistogramVec {
+	s.mu <- struct{}{}
+	defer func() { <-s.mu }()
+
+	name := s.qualify(stat)
+	h, present := s.timings[name]
+	if !present {
+		h = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Buckets: DefaultLatencyBuckets,
+		}, nil)
+		s.reg.MustRegister(h)
+		s.timings[name] = h
+	}
+	return h
+}
+
+// Inc implements Scope.
+func (s *scope) Inc(stat string, n int64) {
+	s.counter(stat).WithLabelValues().Add(float64(n))
+	if s.statter != nil {
+		_ = s.statter.Inc(stat, n, 1.0)
+	}
+}
+
+// Gauge implements Scope.
+func (s *scope) Gauge(stat string, value float64) {
+	s.gauge(stat).WithLabelValues().Set(value)
+	if s.statter != nil {
+		_ = s.statter.Gauge(stat, int64(value), 1.0)
+	}
+}
+
+// Timing implements Scope.
+func (s *scope) Timing(stat string, delta time.Duration) {
+	s.timing(stat).WithLabelValues().Observe(delta.Seconds())
+	if s.statter != nil {
+		// statsd.Statter.Timing truncates to whole milliseconds, which
+		// makes RPCs under 1ms - common for in-process calls - all
+		// report as 0. Emit the raw stat line ourselves with decimal
+		// millisecond precision instead.
+		ms := float64(delta) / float64(time.Millisecond)
+		_ = s.statter.Raw(stat, fmt.Sprintf("%.3f|ms", ms), 1.0)
+	}
+}
+
+// DefaultLatencyBuckets covers sub-millisecond in-process RPCs through
+// multi-second CAA lookups and issuance operations.
+var DefaultLatencyBuckets = []float64{
+	.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10,
+}